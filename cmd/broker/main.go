@@ -0,0 +1,194 @@
+// Command broker is the coffee-demo's single front door. It exposes
+// /handle, which dispatches a JSON action payload to whichever backend
+// service (reporting-service or logger-service) should handle it.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/logclient"
+	"github.com/gorilla/mux"
+)
+
+var (
+	verbose         bool
+	listenAddr      string
+	reportingAddr   string
+	loggerAddr      string
+	loggerTransport string
+)
+
+// requestPayload is the envelope accepted by /handle. Action selects which
+// of the optional sub-payloads is used.
+type requestPayload struct {
+	Action string       `json:"action"`
+	Log    *logPayload  `json:"log,omitempty"`
+	Report *struct{}    `json:"report,omitempty"`
+	Auth   *authPayload `json:"auth,omitempty"`
+}
+
+type logPayload struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+type authPayload struct {
+	EmployeeID string `json:"employeeId"`
+	Password   string `json:"password"`
+}
+
+type broker struct {
+	log       *logrus.Logger
+	logger    logclient.Client
+	reportURL string
+	loginURL  string
+	http      *http.Client
+}
+
+func (b *broker) handle(w http.ResponseWriter, r *http.Request) {
+	var payload requestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		b.log.Error("Error decoding request payload: ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch payload.Action {
+	case "log":
+		b.handleLog(w, r.Context(), payload.Log)
+	case "report":
+		b.handleReport(w, r.Context(), r.Header.Get("Authorization"))
+	case "auth":
+		b.handleAuth(w, r.Context(), payload.Auth)
+	default:
+		b.log.Error("Unknown action: ", payload.Action)
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (b *broker) handleLog(w http.ResponseWriter, ctx context.Context, log *logPayload) {
+	if log == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := b.logger.Log(ctx, log.Name, log.Data); err != nil {
+		b.log.Error("Error forwarding log event: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (b *broker) handleReport(w http.ResponseWriter, ctx context.Context, authHeader string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.reportURL, nil)
+	if err != nil {
+		b.log.Error("Error building reporting-service request: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		b.log.Error("Error calling reporting-service: ", err)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		b.log.Error("Error copying reporting-service response: ", err)
+	}
+}
+
+func (b *broker) handleAuth(w http.ResponseWriter, ctx context.Context, auth *authPayload) {
+	if auth == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := json.Marshal(auth)
+	if err != nil {
+		b.log.Error("Error encoding auth payload: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.loginURL, bytes.NewReader(body))
+	if err != nil {
+		b.log.Error("Error building reporting-service request: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		b.log.Error("Error calling reporting-service: ", err)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		b.log.Error("Error copying reporting-service response: ", err)
+	}
+}
+
+func (b *broker) getRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/handle", b.handle).Methods("POST")
+	return r
+}
+
+func run(log *logrus.Logger) {
+	loggerClient, err := logclient.New(logclient.Transport(loggerTransport), loggerAddr)
+	if err != nil {
+		log.Fatal("Error creating logger-service client: ", err)
+	}
+
+	b := &broker{
+		log:       log,
+		logger:    loggerClient,
+		reportURL: fmt.Sprintf("%s/report", reportingAddr),
+		loginURL:  fmt.Sprintf("%s/auth/login", reportingAddr),
+		http:      &http.Client{},
+	}
+
+	log.Info("Starting HTTP server on ", listenAddr)
+	log.Error("HTTP server shutdown: ", http.ListenAndServe(listenAddr, b.getRouter()))
+}
+
+func main() {
+	flag.Parse()
+
+	log := logrus.New()
+	if verbose {
+		log.Level = logrus.DebugLevel
+		log.Debug("Logging level set to debug")
+	}
+	run(log)
+}
+
+func init() {
+	flag.BoolVar(&verbose, "verbose", false, "Verbose logging")
+	flag.StringVar(&listenAddr, "addr", ":8080", "Address to listen on")
+	flag.StringVar(&reportingAddr, "reporting-addr", "http://localhost:5000", "Base URL of the reporting-service")
+	flag.StringVar(&loggerAddr, "logger-addr", "http://localhost:8081", "Address of the logger-service")
+	flag.StringVar(&loggerTransport, "logger-transport", "http", "Transport to use when calling the logger-service (http or grpc)")
+}
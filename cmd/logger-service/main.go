@@ -0,0 +1,73 @@
+// Command logger-service owns all writes to the coffee-demo `logs`
+// collection in MongoDB. It accepts events from other coffee-demo
+// services over HTTP JSON (POST /log) and gRPC (logs.LogService/WriteLog).
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/logs"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/logserver"
+	"github.com/gorilla/mux"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"google.golang.org/grpc"
+)
+
+var (
+	verbose         bool
+	listenAddr      string
+	grpcListenAddr  string
+	mongoConnString string
+)
+
+func run(log *logrus.Logger) {
+	db, err := mongo.NewClient(mongoConnString)
+	if err != nil {
+		log.Fatal("Error creating mongodb connection: ", err)
+	}
+	if err := db.Connect(context.TODO()); err != nil {
+		log.Fatal("Error creating mongodb connection: ", err)
+	}
+	log.Info("Created mongodb connection for ", mongoConnString)
+
+	srv := logserver.New(log, db)
+
+	go func() {
+		lis, err := net.Listen("tcp", grpcListenAddr)
+		if err != nil {
+			log.Fatal("Error listening for grpc on ", grpcListenAddr, ": ", err)
+		}
+		grpcServer := grpc.NewServer()
+		logs.RegisterLogServiceServer(grpcServer, logserver.NewGRPCHandler(srv))
+		log.Info("Starting gRPC server on ", grpcListenAddr)
+		log.Error("gRPC server shutdown: ", grpcServer.Serve(lis))
+	}()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/log", srv.HTTPHandler).Methods("POST")
+
+	log.Info("Starting HTTP server on ", listenAddr)
+	log.Error("HTTP server shutdown: ", http.ListenAndServe(listenAddr, r))
+}
+
+func main() {
+	flag.Parse()
+
+	log := logrus.New()
+	if verbose {
+		log.Level = logrus.DebugLevel
+		log.Debug("Logging level set to debug")
+	}
+	run(log)
+}
+
+func init() {
+	flag.BoolVar(&verbose, "verbose", false, "Verbose logging")
+	flag.StringVar(&listenAddr, "addr", ":8081", "Address to listen on for HTTP")
+	flag.StringVar(&grpcListenAddr, "grpc-addr", ":9091", "Address to listen on for gRPC")
+	flag.StringVar(&mongoConnString, "mongo", "mongodb://localhost:27017", "Connection string for mongodb server")
+}
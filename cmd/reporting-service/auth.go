@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cgascoig/coffee-demo-reporting/pkg/auth"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+// requireAuth gates handler behind a valid, unrevoked bearer token,
+// injecting its claims into the request context for handler to read via
+// auth.ClaimsFromContext.
+func (rs *reportingServer) requireAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rs.verifier == nil {
+			rs.log.Error("Rejecting request: no JWT verifier configured (-jwt-secret or -jwt-jwks-url)")
+			http.Error(w, "authentication not configured", http.StatusInternalServerError)
+			return
+		}
+
+		tokenString := bearerToken(r)
+		if tokenString == "" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := rs.verifier.Verify(tokenString)
+		if err != nil {
+			rs.log.Debug("Rejecting request: ", err)
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		if rs.revocation != nil {
+			revoked, err := rs.revocation.IsRevoked(r.Context(), claims.Id)
+			if err != nil {
+				rs.log.Error("Error checking token revocation: ", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				http.Error(w, "token revoked", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		handler(w, r.WithContext(auth.ContextWithClaims(r.Context(), claims)))
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(h, prefix)
+}
+
+type loginRequest struct {
+	EmployeeID string `json:"employeeId"`
+	Password   string `json:"password"`
+}
+
+type loginResponse struct {
+	Token string `json:"token"`
+}
+
+// loginHandler implements POST /auth/login: it exchanges an employeeId/
+// password pair for a signed token, which the caller then sends as a
+// bearer token to /report and /report/stream.
+func (rs *reportingServer) loginHandler(w http.ResponseWriter, r *http.Request) {
+	if rs.signer == nil {
+		rs.log.Error("Rejecting login: no Signer configured (set -jwt-secret)")
+		http.Error(w, "login not configured", http.StatusNotImplemented)
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "malformed request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTimeout)
+	defer cancel()
+
+	account, err := rs.store.Authenticate(ctx, req.EmployeeID, req.Password)
+	if err != nil {
+		if err != reportstore.ErrInvalidCredentials {
+			rs.log.Error("Error authenticating employee: ", err)
+		}
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	role := auth.Role(account.Role)
+	if role == "" {
+		role = auth.RoleEmployee
+	}
+
+	token, err := rs.signer.Sign(auth.Claims{
+		EmployeeID:     account.EmployeeID,
+		Role:           role,
+		StandardClaims: jwt.StandardClaims{Id: uuid.New().String()},
+	})
+	if err != nil {
+		rs.log.Error("Error signing token: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loginResponse{Token: token})
+}
+
+// logoutHandler implements POST /auth/logout: it revokes the caller's
+// bearer token so requireAuth rejects it on every later request, even
+// though it hasn't expired yet.
+func (rs *reportingServer) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	if rs.revocation == nil {
+		rs.log.Error("Rejecting logout: no RevocationList configured (set -store=mongo)")
+		http.Error(w, "logout not configured", http.StatusNotImplemented)
+		return
+	}
+
+	claims := auth.ClaimsFromContext(r.Context())
+	if claims == nil {
+		http.Error(w, "missing token claims", http.StatusUnauthorized)
+		return
+	}
+
+	if err := rs.revocation.Revoke(r.Context(), claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		rs.log.Error("Error revoking token: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
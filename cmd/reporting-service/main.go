@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/auth"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/logclient"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore/memstore"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore/mongostore"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore/postgresstore"
+	"github.com/gorilla/mux"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+var (
+	verbose         bool
+	tls             bool
+	certFilename    string
+	certKeyFilename string
+	listenAddr      string
+	mongoConnString string
+
+	logTransport string
+	loggerAddr   string
+
+	shutdownTimeout   time.Duration
+	readHeaderTimeout time.Duration
+
+	logFormat string
+
+	maxReportLimit int
+
+	useWebSocket bool
+
+	store    string
+	storeDSN string
+
+	jwtSecret  string
+	jwtJWKSURL string
+	jwtTTL     time.Duration
+)
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests handled, by method, path and status.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by method, path and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	mongoQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongo_query_duration_seconds",
+		Help:    "Report store query latency in seconds, by operation, for whichever -store backs /report.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"op"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, mongoQueryDuration)
+}
+
+const (
+	dbName                 = "coffee-demo"
+	ordersCollectionName   = "orders"
+	accountsCollectionName = "employeeAccounts"
+	dbTimeout              = 5 * time.Second
+
+	reportOrderCount = 5
+)
+
+type reportingServer struct {
+	log *logrus.Logger
+
+	// store backs /report; selected by -store (mongo, postgres, or memory).
+	store reportstore.Repository
+
+	// mongo is set only when -store=mongo, and also backs the
+	// /report/stream change-stream hub (streaming is mongo-only).
+	mongo *mongo.Client
+
+	// logger-service client, nil if no -logger-addr was configured
+	logger logclient.Client
+
+	// shutdownCtx is cancelled once shutdown begins, so in-flight
+	// reportHandler requests tear down their DB queries promptly.
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+
+	// hub fans out live totals to /report/stream subscribers, nil if
+	// mongo isn't configured.
+	hub *streamHub
+
+	// verifier validates bearer tokens on /report and /report/stream; when
+	// nil, requireAuth rejects every request.
+	verifier auth.Verifier
+	// signer issues tokens for /auth/login; nil (when -jwt-jwks-url is
+	// used instead of -jwt-secret, or neither is set) disables login.
+	signer auth.Signer
+	// revocation lets a previously issued token be rejected early; nil
+	// when -store isn't mongo, since it's Mongo-backed.
+	revocation auth.RevocationList
+}
+
+// logEvent posts a structured event to the logger-service, if configured.
+// Failures are logged locally but never fail the caller's request.
+func (rs *reportingServer) logEvent(ctx context.Context, name string, data string) {
+	if rs.logger == nil {
+		return
+	}
+	if err := rs.logger.Log(ctx, name, data); err != nil {
+		rs.log.Error("Error sending event to logger-service: ", err)
+	}
+}
+
+func (rs *reportingServer) reportHandler(w http.ResponseWriter, r *http.Request) {
+	res := report{}
+
+	ctx, cancel := context.WithTimeout(rs.shutdownCtx, dbTimeout)
+	defer cancel()
+
+	rs.logEvent(ctx, "request received", r.RequestURI)
+
+	q, err := parseReportQuery(r.URL.Query(), maxReportLimit)
+	if err != nil {
+		rs.log.Error("Error parsing report query: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter := q.filter()
+
+	// An employee only ever sees their own orders and account, regardless
+	// of what the caller asked for; an admin sees the full company report.
+	accountsEmployeeID := ""
+	if claims := auth.ClaimsFromContext(r.Context()); claims != nil && claims.Role == auth.RoleEmployee {
+		filter.EmployeeID = claims.EmployeeID
+		accountsEmployeeID = claims.EmployeeID
+	}
+
+	// Find the most recent orders matching the requested filters, sorted
+	// newest-first, applying the caller's pagination.
+	queryStart := time.Now()
+	orders, err := rs.store.RecentOrders(ctx, filter)
+	mongoQueryDuration.WithLabelValues("find-orders").Observe(time.Since(queryStart).Seconds())
+	if err != nil {
+		rs.log.Error("Error querying database: ", err)
+		rs.logEvent(ctx, "db error", err.Error())
+		fmt.Fprintf(w, "[]")
+		return
+	}
+	res.RecentSales = orders
+
+	queryStart = time.Now()
+	accounts, err := rs.store.Accounts(ctx, accountsEmployeeID)
+	mongoQueryDuration.WithLabelValues("find-accounts").Observe(time.Since(queryStart).Seconds())
+	if err != nil {
+		rs.log.Error("Error querying database: ", err)
+		rs.logEvent(ctx, "db error", err.Error())
+		fmt.Fprintf(w, "[]")
+		return
+	}
+	res.EmployeeAccounts = accounts
+
+	queryStart = time.Now()
+	totals, err := rs.store.Totals(ctx, filter)
+	mongoQueryDuration.WithLabelValues("aggregate-totals").Observe(time.Since(queryStart).Seconds())
+	if err != nil {
+		rs.log.Error("Error querying database: ", err)
+		rs.logEvent(ctx, "db error", err.Error())
+		fmt.Fprintf(w, "[]")
+		return
+	}
+	res.TotalSales = totals.TotalSales
+	res.TotalRevenue = totals.TotalRevenue
+
+	if q.GroupBy != "" {
+		queryStart = time.Now()
+		timeseries, err := rs.store.Timeseries(ctx, filter, q.GroupBy)
+		mongoQueryDuration.WithLabelValues("aggregate-timeseries").Observe(time.Since(queryStart).Seconds())
+		if err != nil {
+			rs.log.Error("Error running timeseries aggregation: ", err)
+			rs.logEvent(ctx, "db error", err.Error())
+		} else {
+			res.Timeseries = timeseries
+		}
+	}
+
+	jsResults, err := json.Marshal(res)
+	if err != nil {
+		rs.log.Error("Error querying results from mongodb: ", err)
+		fmt.Fprintf(w, "[]")
+		return
+	}
+
+	if string(jsResults) == "null" {
+		jsResults = []byte("[]")
+	}
+
+	rs.logEvent(ctx, "report generated", string(jsResults))
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Printf("Sending response: %v, JSON: %v\n", res, string(jsResults))
+	fmt.Fprint(w, string(jsResults))
+}
+
+// statusRecorder wraps an http.ResponseWriter to capture the status code and
+// byte count written, for metrics and structured logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytesWritten += n
+	return n, err
+}
+
+// Flush delegates to the wrapped ResponseWriter if it implements
+// http.Flusher, so middleware-wrapped handlers (e.g. streamHandler's SSE
+// transport) can still stream.
+func (rec *statusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack delegates to the wrapped ResponseWriter if it implements
+// http.Hijacker, so middleware-wrapped handlers (e.g. streamHandler's
+// WebSocket transport) can still upgrade the connection.
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not support hijacking")
+	}
+	return hj.Hijack()
+}
+
+// loggingHandler is a middleware chain that logs each request with
+// request-scoped fields and records Prometheus request metrics.
+func (rs *reportingServer) loggingHandler(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := uuid.New().String()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		rs.log.WithFields(logrus.Fields{"request_id": requestID, "method": r.Method, "uri": r.RequestURI}).Info("Handling request")
+		handler(rec, r)
+		latency := time.Since(start)
+
+		path := r.URL.Path
+		status := fmt.Sprint(rec.status)
+		httpRequestsTotal.WithLabelValues(r.Method, path, status).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, path, status).Observe(latency.Seconds())
+
+		rs.log.WithFields(logrus.Fields{
+			"request_id":    requestID,
+			"remote_addr":   r.RemoteAddr,
+			"latency":       latency.String(),
+			"bytes_written": rec.bytesWritten,
+			"status":        rec.status,
+		}).Debug("Finished handling request")
+	}
+}
+
+func (rs *reportingServer) getRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/report", rs.loggingHandler(rs.requireAuth(rs.reportHandler))).Methods("GET")
+	r.HandleFunc("/report/stream", rs.loggingHandler(rs.requireAuth(rs.streamHandler))).Methods("GET")
+	r.HandleFunc("/auth/login", rs.loggingHandler(rs.loginHandler)).Methods("POST")
+	r.HandleFunc("/auth/logout", rs.loggingHandler(rs.requireAuth(rs.logoutHandler))).Methods("POST")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
+
+	return r
+}
+
+func newReportingServer(log *logrus.Logger) *reportingServer {
+	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
+	rs := reportingServer{
+		log:            log,
+		shutdownCtx:    shutdownCtx,
+		shutdownCancel: shutdownCancel,
+	}
+
+	switch store {
+	case "mongo":
+		dsn := storeDSN
+		if dsn == "" {
+			dsn = mongoConnString
+		}
+
+		db, err := mongo.NewClient(dsn)
+		if err != nil {
+			log.Error("Error creating mongodb connection: ", err)
+			return nil
+		}
+		if err := db.Connect(context.TODO()); err != nil {
+			log.Error("Error creating mongodb connection: ", err)
+			return nil
+		}
+		log.Info("Created mongodb connection for ", dsn)
+
+		rs.mongo = db
+		rs.store = mongostore.New(db)
+		rs.hub = newStreamHub(log, db)
+		rs.hub.run(rs.shutdownCtx)
+		rs.revocation = auth.NewMongoRevocationList(db)
+	case "postgres":
+		s, err := postgresstore.New(storeDSN)
+		if err != nil {
+			log.Error("Error connecting to postgres: ", err)
+			return nil
+		}
+		log.Info("Connected to postgres store")
+		rs.store = s
+	case "memory":
+		log.Info("Using in-memory store")
+		rs.store = memstore.New()
+	default:
+		log.Error("Unknown -store: ", store)
+		return nil
+	}
+
+	switch {
+	case jwtJWKSURL != "":
+		log.Info("Verifying RS256 tokens against jwks at ", jwtJWKSURL)
+		rs.verifier = auth.NewJWKSAuth(jwtJWKSURL)
+	case jwtSecret != "":
+		log.Info("Verifying and issuing HS256 tokens")
+		hmac := auth.NewHMACAuth(jwtSecret, jwtTTL)
+		rs.verifier = hmac
+		rs.signer = hmac
+	default:
+		log.Warn("No -jwt-secret or -jwt-jwks-url set; /report, /report/stream and /auth/login will reject every request")
+	}
+
+	if loggerAddr != "" {
+		client, err := logclient.New(logclient.Transport(logTransport), loggerAddr)
+		if err != nil {
+			log.Error("Error creating logger-service client: ", err)
+			return nil
+		}
+		log.Info("Sending events to logger-service at ", loggerAddr, " over ", logTransport)
+		rs.logger = client
+	}
+
+	return &rs
+}
+
+func run(log *logrus.Logger) {
+	cs := newReportingServer(log)
+	r := cs.getRouter()
+
+	srv := &http.Server{
+		Addr:              listenAddr,
+		Handler:           r,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if tls {
+			log.Info("Starting HTTPS server on ", listenAddr)
+			serveErr <- srv.ListenAndServeTLS(certFilename, certKeyFilename)
+		} else {
+			log.Info("Starting HTTP server on ", listenAddr)
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serveErr:
+		log.Error("HTTP server exited: ", err)
+	case sig := <-sigCh:
+		log.Info("Received signal ", sig, ", shutting down")
+	}
+
+	cs.shutdownCancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("Error draining in-flight requests: ", err)
+	}
+
+	if cs.mongo != nil {
+		if err := cs.mongo.Disconnect(ctx); err != nil {
+			log.Error("Error disconnecting from mongodb: ", err)
+		}
+	}
+
+	if closer, ok := cs.store.(*postgresstore.Store); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("Error closing postgres connection: ", err)
+		}
+	}
+
+	if cs.logger != nil {
+		if err := cs.logger.Close(); err != nil {
+			log.Error("Error closing logger-service client: ", err)
+		}
+	}
+
+	log.Info("Shutdown complete")
+}
+
+func main() {
+	flag.Parse()
+
+	log := logrus.New()
+	if logFormat == "json" {
+		log.Formatter = &logrus.JSONFormatter{}
+	}
+	if verbose {
+		log.Level = logrus.DebugLevel
+		log.Debug("Logging level set to debug")
+	}
+	run(log)
+}
+
+func init() {
+	flag.BoolVar(&verbose, "verbose", false, "Verbose logging")
+	flag.StringVar(&listenAddr, "addr", ":5000", "Address to listen on")
+	flag.StringVar(&mongoConnString, "mongo", "mongodb://localhost:27017", "Connection string for mondodb server")
+
+	flag.BoolVar(&tls, "tls", false, "Enable TLS")
+	flag.StringVar(&certFilename, "cert", "", "Filename for certificate file (e.g. cert.pem)")
+	flag.StringVar(&certKeyFilename, "certkey", "", "Filename for certificate private key file (e.g. key.pem)")
+
+	flag.StringVar(&logTransport, "log-transport", "http", "Transport to use when sending events to the logger-service (http or grpc)")
+	flag.StringVar(&loggerAddr, "logger-addr", "", "Address of the logger-service (e.g. http://localhost:8081 for http, localhost:9091 for grpc). Leave empty to disable event logging")
+
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 5*time.Second, "Time to wait for in-flight requests to drain before forcing shutdown")
+	flag.DurationVar(&readHeaderTimeout, "read-header-timeout", 5*time.Second, "Time to wait for request headers before timing out")
+
+	flag.StringVar(&logFormat, "log-format", "text", "Log output format (text or json)")
+
+	flag.IntVar(&maxReportLimit, "max-report-limit", 100, "Maximum value accepted for the report's ?limit= parameter")
+
+	flag.BoolVar(&useWebSocket, "ws", false, "Serve /report/stream over WebSocket instead of Server-Sent Events")
+
+	flag.StringVar(&store, "store", "mongo", "Storage backend for /report (mongo, postgres, or memory)")
+	flag.StringVar(&storeDSN, "store-dsn", "", "Connection string for the selected -store. Defaults to -mongo when -store=mongo")
+
+	flag.StringVar(&jwtSecret, "jwt-secret", "", "Shared secret for issuing and verifying HS256 tokens. Required for /auth/login")
+	flag.StringVar(&jwtJWKSURL, "jwt-jwks-url", "", "JWKS URL to verify RS256 tokens from an external identity provider instead of -jwt-secret. Disables /auth/login")
+	flag.DurationVar(&jwtTTL, "jwt-ttl", time.Hour, "Lifetime of tokens issued by /auth/login")
+}
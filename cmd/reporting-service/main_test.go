@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/auth"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore/memstore"
+)
+
+func testReportingServer(t *testing.T) *reportingServer {
+	t.Helper()
+	store := memstore.New()
+	store.Seed([]reportstore.Order{
+		{ID: "1", CoffeeType: "latte", CoffeeQty: 2, EmployeeID: "alice", Amount: 6.5},
+		{ID: "2", CoffeeType: "drip", CoffeeQty: 1, EmployeeID: "bob", Amount: 2.5},
+	}, []reportstore.Account{
+		{ID: "a1", EmployeeID: "alice", Balance: 10, Name: "Alice"},
+		{ID: "a2", EmployeeID: "bob", Balance: 20, Name: "Bob"},
+	})
+
+	log := logrus.New()
+	log.Out = io.Discard
+
+	return &reportingServer{
+		log:         log,
+		store:       store,
+		shutdownCtx: context.Background(),
+	}
+}
+
+// TestReportHandlerScopesEmployeeToOwnData covers reportHandler's
+// role-scoping: an employee-role token must only ever see their own
+// orders and account, regardless of what the request asked for.
+func TestReportHandlerScopesEmployeeToOwnData(t *testing.T) {
+	rs := testReportingServer(t)
+
+	req := httptest.NewRequest("GET", "/report?employeeId=bob", nil)
+	claims := &auth.Claims{EmployeeID: "alice", Role: auth.RoleEmployee}
+	req = req.WithContext(auth.ContextWithClaims(req.Context(), claims))
+
+	rec := httptest.NewRecorder()
+	rs.reportHandler(rec, req)
+
+	var res report
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decoding response: %v (body=%s)", err, rec.Body.String())
+	}
+
+	if res.TotalSales != 2 {
+		t.Errorf("TotalSales = %d, want 2 (alice's orders only)", res.TotalSales)
+	}
+	if len(res.EmployeeAccounts) != 1 || res.EmployeeAccounts[0].EmployeeID != "alice" {
+		t.Errorf("EmployeeAccounts = %+v, want only alice's account", res.EmployeeAccounts)
+	}
+}
+
+func TestReportHandlerAdminSeesEverything(t *testing.T) {
+	rs := testReportingServer(t)
+
+	req := httptest.NewRequest("GET", "/report", nil)
+	claims := &auth.Claims{EmployeeID: "alice", Role: auth.RoleAdmin}
+	req = req.WithContext(auth.ContextWithClaims(req.Context(), claims))
+
+	rec := httptest.NewRecorder()
+	rs.reportHandler(rec, req)
+
+	var res report
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decoding response: %v (body=%s)", err, rec.Body.String())
+	}
+
+	if res.TotalSales != 3 {
+		t.Errorf("TotalSales = %d, want 3 (all orders)", res.TotalSales)
+	}
+	if len(res.EmployeeAccounts) != 2 {
+		t.Errorf("len(EmployeeAccounts) = %d, want 2", len(res.EmployeeAccounts))
+	}
+}
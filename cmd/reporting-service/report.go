@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+)
+
+type report struct {
+	TotalSales       int                           `json:"totalsales"`
+	TotalRevenue     float32                       `json:"totalrevenue"`
+	RecentSales      []reportstore.Order           `json:"recentsales"`
+	EmployeeAccounts []reportstore.Account         `json:"employeeaccounts"`
+	Timeseries       []reportstore.TimeseriesPoint `json:"timeseries,omitempty"`
+}
+
+const defaultReportLimit = reportOrderCount
+
+// reportQuery holds the parsed, validated query parameters accepted by
+// /report: a date range, pagination, filters, and an optional groupBy mode.
+type reportQuery struct {
+	From, To       time.Time
+	HasFrom, HasTo bool
+	Limit, Offset  int
+	EmployeeID     string
+	CoffeeType     string
+	GroupBy        reportstore.GroupBy
+}
+
+// parseReportQuery validates and parses the /report query parameters,
+// capping Limit at maxLimit. It returns an error describing the first
+// malformed parameter found.
+func parseReportQuery(values url.Values, maxLimit int) (reportQuery, error) {
+	q := reportQuery{
+		Limit:      defaultReportLimit,
+		EmployeeID: values.Get("employeeId"),
+		CoffeeType: values.Get("coffeeType"),
+		GroupBy:    reportstore.GroupBy(values.Get("groupBy")),
+	}
+
+	if from := values.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return q, fmt.Errorf("invalid from: %w", err)
+		}
+		q.From, q.HasFrom = t, true
+	}
+
+	if to := values.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return q, fmt.Errorf("invalid to: %w", err)
+		}
+		q.To, q.HasTo = t, true
+	}
+
+	if q.HasFrom && q.HasTo && q.From.After(q.To) {
+		return q, fmt.Errorf("from must not be after to")
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return q, fmt.Errorf("invalid limit: %q", limit)
+		}
+		q.Limit = n
+	}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid offset: %q", offset)
+		}
+		q.Offset = n
+	}
+
+	switch q.GroupBy {
+	case "", reportstore.GroupByDay, reportstore.GroupByWeek, reportstore.GroupByEmployee, reportstore.GroupByCoffeeType:
+	default:
+		return q, fmt.Errorf("invalid groupBy: %q", q.GroupBy)
+	}
+
+	return q, nil
+}
+
+// filter converts q into the reportstore.Filter its backend-agnostic
+// Repository methods expect.
+func (q reportQuery) filter() reportstore.Filter {
+	return reportstore.Filter{
+		From:       q.From,
+		To:         q.To,
+		HasFrom:    q.HasFrom,
+		HasTo:      q.HasTo,
+		EmployeeID: q.EmployeeID,
+		CoffeeType: q.CoffeeType,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+	}
+}
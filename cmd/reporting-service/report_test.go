@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseReportQueryDefaults(t *testing.T) {
+	q, err := parseReportQuery(url.Values{}, 100)
+	if err != nil {
+		t.Fatalf("parseReportQuery: %v", err)
+	}
+	if q.Limit != defaultReportLimit {
+		t.Errorf("Limit = %d, want default %d", q.Limit, defaultReportLimit)
+	}
+	if q.HasFrom || q.HasTo {
+		t.Errorf("HasFrom/HasTo = %v/%v, want false/false", q.HasFrom, q.HasTo)
+	}
+}
+
+func TestParseReportQueryLimitCap(t *testing.T) {
+	q, err := parseReportQuery(url.Values{"limit": {"500"}}, 100)
+	if err != nil {
+		t.Fatalf("parseReportQuery: %v", err)
+	}
+	if q.Limit != 100 {
+		t.Errorf("Limit = %d, want capped at 100", q.Limit)
+	}
+}
+
+func TestParseReportQueryFromTo(t *testing.T) {
+	q, err := parseReportQuery(url.Values{
+		"from": {"2026-01-01T00:00:00Z"},
+		"to":   {"2026-01-31T00:00:00Z"},
+	}, 100)
+	if err != nil {
+		t.Fatalf("parseReportQuery: %v", err)
+	}
+	if !q.HasFrom || !q.HasTo {
+		t.Fatalf("HasFrom/HasTo = %v/%v, want true/true", q.HasFrom, q.HasTo)
+	}
+	if !q.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("From = %v, want 2026-01-01", q.From)
+	}
+}
+
+func TestParseReportQueryInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		values url.Values
+	}{
+		{"bad from", url.Values{"from": {"not-a-date"}}},
+		{"bad to", url.Values{"to": {"not-a-date"}}},
+		{"from after to", url.Values{"from": {"2026-01-31T00:00:00Z"}, "to": {"2026-01-01T00:00:00Z"}}},
+		{"bad limit", url.Values{"limit": {"nope"}}},
+		{"zero limit", url.Values{"limit": {"0"}}},
+		{"bad offset", url.Values{"offset": {"nope"}}},
+		{"negative offset", url.Values{"offset": {"-1"}}},
+		{"bad groupBy", url.Values{"groupBy": {"year"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := parseReportQuery(c.values, 100); err == nil {
+				t.Errorf("parseReportQuery(%v) = nil error, want error", c.values)
+			}
+		})
+	}
+}
+
+func TestParseReportQueryGroupByValid(t *testing.T) {
+	for _, gb := range []string{"", "day", "week", "employee", "coffeetype"} {
+		if _, err := parseReportQuery(url.Values{"groupBy": {gb}}, 100); err != nil {
+			t.Errorf("parseReportQuery(groupBy=%q): %v", gb, err)
+		}
+	}
+}
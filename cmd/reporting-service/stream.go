@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore/mongostore"
+	"github.com/gorilla/websocket"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/changestreamopt"
+)
+
+const (
+	streamHeartbeatInterval = 15 * time.Second
+	streamClientBuffer      = 16
+)
+
+// changeEvent is the subset of a MongoDB change stream document this
+// service cares about. ResumeToken/FullDocument use bson.Reader, not the
+// newer bson.Raw, to match the pre-1.0 driver API (bson.EC/VC/NewDocument)
+// the rest of this series depends on.
+type changeEvent struct {
+	ResumeToken   bson.Reader `bson:"_id"`
+	OperationType string      `bson:"operationType"`
+	FullDocument  bson.Reader `bson:"fullDocument"`
+}
+
+// changeOrder and changeAccount mirror mongostore's decode shape, since the
+// shared reportstore.Order/Account types intentionally carry no bson tags.
+type changeOrder struct {
+	ID         string  `bson:"_id,omitempty"`
+	CoffeeType string  `bson:"coffeetype"`
+	CoffeeQty  int     `bson:"coffeeqty"`
+	EmployeeID string  `bson:"employeeId"`
+	Amount     float32 `bson:"amount"`
+}
+
+type changeAccount struct {
+	ID         string  `bson:"_id,omitempty"`
+	EmployeeID string  `bson:"employeeId"`
+	Balance    float32 `bson:"balance"`
+	Name       string  `bson:"name"`
+}
+
+// streamEvent is what gets pushed out to subscribed clients, as a single
+// JSON-encodable snapshot of the running totals.
+type streamEvent struct {
+	ID               string                `json:"-"`
+	TotalSales       int                   `json:"totalsales"`
+	TotalRevenue     float32               `json:"totalrevenue"`
+	RecentSales      []reportstore.Order   `json:"recentsales"`
+	EmployeeAccounts []reportstore.Account `json:"employeeaccounts"`
+}
+
+// streamHub maintains one live view of the coffee-demo totals, fed by a
+// single pair of MongoDB change streams (orders, employeeAccounts), and
+// fans updates out to any number of subscribed clients so they share that
+// one pair of cursors instead of each polling /report.
+type streamHub struct {
+	log   *logrus.Logger
+	mongo *mongo.Client
+
+	mu                sync.Mutex
+	totalSales        int
+	totalRevenue      float32
+	recentSales       []reportstore.Order
+	employeeAccounts  map[string]reportstore.Account
+	ordersResumeToken bson.Reader
+	acctsResumeToken  bson.Reader
+	seq               int
+
+	clientsMu sync.Mutex
+	clients   map[chan streamEvent]struct{}
+}
+
+func newStreamHub(log *logrus.Logger, mongoClient *mongo.Client) *streamHub {
+	return &streamHub{
+		log:              log,
+		mongo:            mongoClient,
+		employeeAccounts: make(map[string]reportstore.Account),
+		clients:          make(map[chan streamEvent]struct{}),
+	}
+}
+
+// seed populates the hub from the current contents of the orders and
+// employeeAccounts collections, so subscribers see correct totals
+// immediately instead of only the deltas from changes made after startup.
+func (h *streamHub) seed(ctx context.Context) error {
+	store := mongostore.New(h.mongo)
+
+	orders, err := store.RecentOrders(ctx, reportstore.Filter{Limit: reportOrderCount})
+	if err != nil {
+		return fmt.Errorf("seeding recent orders: %w", err)
+	}
+
+	totals, err := store.Totals(ctx, reportstore.Filter{})
+	if err != nil {
+		return fmt.Errorf("seeding totals: %w", err)
+	}
+
+	accounts, err := store.Accounts(ctx, "")
+	if err != nil {
+		return fmt.Errorf("seeding accounts: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.recentSales = orders
+	h.totalSales = totals.TotalSales
+	h.totalRevenue = totals.TotalRevenue
+	for _, a := range accounts {
+		h.employeeAccounts[a.EmployeeID] = a
+	}
+	return nil
+}
+
+// run seeds the hub from the current collection contents, then watches
+// the orders and employeeAccounts collections until ctx is cancelled,
+// incrementally updating totals and broadcasting a snapshot on every
+// change.
+func (h *streamHub) run(ctx context.Context) {
+	if err := h.seed(ctx); err != nil {
+		h.log.Error("Error seeding stream hub: ", err)
+	}
+
+	ordersCollection := h.mongo.Database(dbName).Collection(ordersCollectionName)
+	accountsCollection := h.mongo.Database(dbName).Collection(accountsCollectionName)
+
+	go h.watch(ctx, ordersCollection, &h.ordersResumeToken, h.applyOrderChange)
+	go h.watch(ctx, accountsCollection, &h.acctsResumeToken, h.applyAccountChange)
+}
+
+// watch opens a change stream on collection and applies every event it
+// receives until ctx is cancelled, retrying the stream on error. It
+// resumes from *resumeToken when set, so a dropped change stream doesn't
+// silently skip whatever changed while it was reconnecting.
+func (h *streamHub) watch(ctx context.Context, collection *mongo.Collection, resumeToken *bson.Reader, apply func(changeEvent)) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var opts []changestreamopt.ChangeStream
+		if doc := h.resumeDocument(resumeToken); doc != nil {
+			opts = append(opts, changestreamopt.ResumeAfter(doc))
+		}
+
+		cursor, err := collection.Watch(ctx, nil, opts...)
+		if err != nil {
+			h.log.Error("Error opening change stream: ", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for cursor.Next(ctx) {
+			var ev changeEvent
+			if err := cursor.Decode(&ev); err != nil {
+				h.log.Error("Error decoding change stream event: ", err)
+				continue
+			}
+			h.mu.Lock()
+			*resumeToken = ev.ResumeToken
+			h.mu.Unlock()
+
+			apply(ev)
+			h.broadcast()
+		}
+
+		cursor.Close(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (h *streamHub) resumeDocument(resumeToken *bson.Reader) *bson.Document {
+	h.mu.Lock()
+	token := *resumeToken
+	h.mu.Unlock()
+
+	if len(token) == 0 {
+		return nil
+	}
+
+	doc, err := bson.ReadDocument(token)
+	if err != nil {
+		h.log.Error("Error parsing resume token: ", err)
+		return nil
+	}
+	return doc
+}
+
+func (h *streamHub) applyOrderChange(ev changeEvent) {
+	if ev.OperationType != "insert" && ev.OperationType != "update" && ev.OperationType != "replace" {
+		return
+	}
+
+	var decoded changeOrder
+	if err := bson.Unmarshal(ev.FullDocument, &decoded); err != nil {
+		h.log.Error("Error decoding order from change stream: ", err)
+		return
+	}
+	order := reportstore.Order{
+		ID:         decoded.ID,
+		CoffeeType: decoded.CoffeeType,
+		CoffeeQty:  decoded.CoffeeQty,
+		EmployeeID: decoded.EmployeeID,
+		Amount:     decoded.Amount,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.totalSales += order.CoffeeQty
+	h.totalRevenue += order.Amount
+
+	h.recentSales = append([]reportstore.Order{order}, h.recentSales...)
+	if len(h.recentSales) > reportOrderCount {
+		h.recentSales = h.recentSales[:reportOrderCount]
+	}
+}
+
+func (h *streamHub) applyAccountChange(ev changeEvent) {
+	if ev.OperationType != "insert" && ev.OperationType != "update" && ev.OperationType != "replace" {
+		return
+	}
+
+	var decoded changeAccount
+	if err := bson.Unmarshal(ev.FullDocument, &decoded); err != nil {
+		h.log.Error("Error decoding account from change stream: ", err)
+		return
+	}
+	account := reportstore.Account{
+		ID:         decoded.ID,
+		EmployeeID: decoded.EmployeeID,
+		Balance:    decoded.Balance,
+		Name:       decoded.Name,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.employeeAccounts[account.EmployeeID] = account
+}
+
+func (h *streamHub) snapshot() streamEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	accounts := make([]reportstore.Account, 0, len(h.employeeAccounts))
+	for _, a := range h.employeeAccounts {
+		accounts = append(accounts, a)
+	}
+
+	return streamEvent{
+		ID:               fmt.Sprint(h.seq),
+		TotalSales:       h.totalSales,
+		TotalRevenue:     h.totalRevenue,
+		RecentSales:      append([]reportstore.Order(nil), h.recentSales...),
+		EmployeeAccounts: accounts,
+	}
+}
+
+// subscribe registers a new client channel and returns it along with an
+// unsubscribe func. The channel is buffered so a slow client can't stall
+// the hub; if its buffer fills, events are dropped for that client.
+func (h *streamHub) subscribe() (chan streamEvent, func()) {
+	ch := make(chan streamEvent, streamClientBuffer)
+
+	h.clientsMu.Lock()
+	h.clients[ch] = struct{}{}
+	h.clientsMu.Unlock()
+
+	return ch, func() {
+		h.clientsMu.Lock()
+		delete(h.clients, ch)
+		h.clientsMu.Unlock()
+		close(ch)
+	}
+}
+
+func (h *streamHub) broadcast() {
+	ev := h.snapshot()
+
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- ev:
+		default:
+			h.log.Debug("Dropping stream event for slow client")
+		}
+	}
+}
+
+// streamHandler implements GET /report/stream, pushing a streamEvent to the
+// client every time the hub's totals change, either as Server-Sent Events
+// or, if -ws was set, as a WebSocket connection.
+func (rs *reportingServer) streamHandler(w http.ResponseWriter, r *http.Request) {
+	if rs.hub == nil {
+		rs.log.Error("Rejecting /report/stream: no stream hub configured (set -store=mongo)")
+		http.Error(w, "streaming not configured", http.StatusNotImplemented)
+		return
+	}
+
+	if useWebSocket {
+		rs.streamWebSocket(w, r)
+		return
+	}
+	rs.streamSSE(w, r)
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// writeSSEEvent writes ev as one SSE frame and flushes it.
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, ev streamEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", ev.ID, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+func (rs *reportingServer) streamSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		rs.log.Debug("Client reconnected from Last-Event-ID ", lastEventID, "; sending current snapshot to catch up")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch, unsubscribe := rs.hub.subscribe()
+	defer unsubscribe()
+
+	// The hub only ever holds the current aggregate state, not a log of
+	// past deltas, so "resuming" a client means catching it up to that
+	// current state immediately rather than replaying what it missed.
+	if err := writeSSEEvent(w, flusher, rs.hub.snapshot()); err != nil {
+		rs.log.Error("Error writing initial stream event: ", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, flusher, ev); err != nil {
+				rs.log.Error("Error writing stream event: ", err)
+				return
+			}
+		}
+	}
+}
+
+func (rs *reportingServer) streamWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		rs.log.Error("Error upgrading to websocket: ", err)
+		return
+	}
+	defer conn.Close()
+
+	ch, unsubscribe := rs.hub.subscribe()
+	defer unsubscribe()
+
+	// See streamSSE: catch the client up to the current snapshot rather
+	// than waiting for the next change.
+	if err := conn.WriteJSON(rs.hub.snapshot()); err != nil {
+		rs.log.Error("Error writing initial websocket message: ", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(ev); err != nil {
+				rs.log.Error("Error writing websocket message: ", err)
+				return
+			}
+		}
+	}
+}
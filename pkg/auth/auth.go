@@ -0,0 +1,203 @@
+// Package auth issues and verifies the JWTs that gate the reporting-
+// service's API, and scopes requests to the bearer's Role/EmployeeID.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// Role scopes what a token's bearer can see via the reporting API.
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleEmployee Role = "employee"
+)
+
+// Claims are the JWT claims this service issues and verifies.
+type Claims struct {
+	EmployeeID string `json:"employeeId"`
+	Role       Role   `json:"role"`
+	jwt.StandardClaims
+}
+
+type contextKey int
+
+const claimsContextKey contextKey = 0
+
+// ContextWithClaims returns a copy of ctx carrying claims, for handlers
+// downstream of the auth middleware.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+// ClaimsFromContext returns the Claims the auth middleware verified for
+// this request, or nil if the request wasn't authenticated.
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsContextKey).(*Claims)
+	return claims
+}
+
+// Verifier validates a bearer token string and returns its claims.
+type Verifier interface {
+	Verify(tokenString string) (*Claims, error)
+}
+
+// Signer issues a signed token for claims, filling in IssuedAt/ExpiresAt.
+type Signer interface {
+	Sign(claims Claims) (string, error)
+}
+
+// HMACAuth signs and verifies tokens with a single shared HS256 secret.
+// It's the Signer behind /auth/login, and the default Verifier unless
+// -jwt-jwks-url selects JWKSAuth instead.
+type HMACAuth struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewHMACAuth returns an HMACAuth issuing tokens that expire after ttl.
+func NewHMACAuth(secret string, ttl time.Duration) *HMACAuth {
+	return &HMACAuth{secret: []byte(secret), ttl: ttl}
+}
+
+// Sign implements Signer.
+func (h *HMACAuth) Sign(claims Claims) (string, error) {
+	now := time.Now()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(h.ttl).Unix()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+}
+
+// Verify implements Verifier.
+func (h *HMACAuth) Verify(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return h.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
+
+// JWKSAuth verifies RS256 tokens issued by an external identity provider,
+// fetching its public keys from a JWKS endpoint and caching them by kid.
+// It cannot sign tokens: when -jwt-jwks-url is set, /auth/login is
+// disabled and tokens are expected to come from that provider instead.
+type JWKSAuth struct {
+	jwksURL string
+	http    *http.Client
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+// NewJWKSAuth returns a JWKSAuth fetching keys from jwksURL.
+func NewJWKSAuth(jwksURL string) *JWKSAuth {
+	return &JWKSAuth{jwksURL: jwksURL, http: &http.Client{Timeout: 5 * time.Second}}
+}
+
+type jwksDocument struct {
+	Keys []jwksKey `json:"keys"`
+}
+
+type jwksKey struct {
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Verify implements Verifier.
+func (j *JWKSAuth) Verify(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return j.key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
+
+func (j *JWKSAuth) key(kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+
+	if err := j.refreshLocked(); err != nil {
+		return nil, err
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q in jwks at %s", kid, j.jwksURL)
+	}
+	return key, nil
+}
+
+// refreshLocked fetches and parses the JWKS document. Callers must hold j.mu.
+func (j *JWKSAuth) refreshLocked() error {
+	resp, err := j.http.Get(j.jwksURL)
+	if err != nil {
+		return fmt.Errorf("fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		pub, err := k.publicKey()
+		if err != nil {
+			return fmt.Errorf("parsing jwks key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+	j.keys = keys
+	return nil
+}
+
+func (k jwksKey) publicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
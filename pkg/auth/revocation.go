@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+const (
+	revocationDBName         = "coffee-demo"
+	revocationCollectionName = "revokedTokens"
+)
+
+// RevocationList tracks revoked token IDs (a JWT's jti claim) until they
+// expire.
+type RevocationList interface {
+	// IsRevoked reports whether jti has been revoked.
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// Revoke marks jti as revoked until expiresAt.
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+}
+
+type revokedToken struct {
+	JTI       string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// MongoRevocationList is a RevocationList backed by MongoDB, with an
+// in-memory cache of tokens known to be revoked so repeated checks for
+// them don't round-trip to the database. A token that isn't in the list
+// is looked up in the database on every check.
+type MongoRevocationList struct {
+	client *mongo.Client
+
+	mu    sync.RWMutex
+	cache map[string]time.Time
+}
+
+// NewMongoRevocationList returns a MongoRevocationList backed by client.
+func NewMongoRevocationList(client *mongo.Client) *MongoRevocationList {
+	return &MongoRevocationList{client: client, cache: make(map[string]time.Time)}
+}
+
+func (r *MongoRevocationList) collection() *mongo.Collection {
+	return r.client.Database(revocationDBName).Collection(revocationCollectionName)
+}
+
+// IsRevoked implements RevocationList.
+func (r *MongoRevocationList) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if expiresAt, ok := r.cached(jti); ok {
+		return time.Now().Before(expiresAt), nil
+	}
+
+	var doc revokedToken
+	filter := bson.NewDocument(bson.EC.String("_id", jti))
+	err := r.collection().FindOne(ctx, filter).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.cache[jti] = doc.ExpiresAt
+	r.mu.Unlock()
+
+	return time.Now().Before(doc.ExpiresAt), nil
+}
+
+// Revoke implements RevocationList.
+func (r *MongoRevocationList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	doc := bson.NewDocument(
+		bson.EC.String("_id", jti),
+		bson.EC.DateTime("expiresAt", expiresAt.Unix()*1000),
+	)
+	if _, err := r.collection().InsertOne(ctx, doc); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cache[jti] = expiresAt
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *MongoRevocationList) cached(jti string) (time.Time, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expiresAt, ok := r.cache[jti]
+	return expiresAt, ok
+}
@@ -0,0 +1,125 @@
+// Package logclient lets other coffee-demo services emit structured log
+// events to the logger-service, over either HTTP JSON or gRPC.
+package logclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cgascoig/coffee-demo-reporting/pkg/logs"
+	"google.golang.org/grpc"
+)
+
+// Transport selects how a Client reaches the logger-service.
+type Transport string
+
+const (
+	// TransportHTTP posts JSON to the logger-service's /log endpoint.
+	TransportHTTP Transport = "http"
+	// TransportGRPC calls the logger-service's LogService.WriteLog RPC.
+	TransportGRPC Transport = "grpc"
+
+	maxRetries   = 3
+	retryBackoff = 200 * time.Millisecond
+)
+
+// Client sends structured events to the logger-service.
+type Client interface {
+	// Log sends a single named event with an arbitrary data payload.
+	Log(ctx context.Context, name string, data string) error
+	Close() error
+}
+
+// New returns a Client for the given transport and peer address. For
+// TransportHTTP, addr is a base URL (e.g. http://logger:8081); for
+// TransportGRPC it's a dial target (e.g. logger:9091).
+func New(transport Transport, addr string) (Client, error) {
+	switch transport {
+	case TransportHTTP:
+		return &httpClient{addr: addr, httpClient: &http.Client{Timeout: 5 * time.Second}}, nil
+	case TransportGRPC:
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("dialing logger-service at %s: %w", addr, err)
+		}
+		return &grpcClient{conn: conn, client: logs.NewLogServiceClient(conn)}, nil
+	default:
+		return nil, fmt.Errorf("unknown log transport %q", transport)
+	}
+}
+
+type httpClient struct {
+	addr       string
+	httpClient *http.Client
+}
+
+func (c *httpClient) Log(ctx context.Context, name string, data string) error {
+	body, err := json.Marshal(struct {
+		Name string `json:"name"`
+		Data string `json:"data"`
+	}{Name: name, Data: data})
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+"/log", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return nil
+			}
+			lastErr = fmt.Errorf("logger-service returned %s", resp.Status)
+		} else {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+	return lastErr
+}
+
+func (c *httpClient) Close() error { return nil }
+
+type grpcClient struct {
+	conn   *grpc.ClientConn
+	client logs.LogServiceClient
+}
+
+func (c *grpcClient) Log(ctx context.Context, name string, data string) error {
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err := c.client.WriteLog(ctx, &logs.LogRequest{Name: name, Data: data})
+		if err == nil {
+			if resp.GetError() != "" {
+				return fmt.Errorf("logger-service: %s", resp.GetError())
+			}
+			return nil
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+	return lastErr
+}
+
+func (c *grpcClient) Close() error { return c.conn.Close() }
@@ -0,0 +1,102 @@
+// Package logserver implements the logger-service's storage and transport
+// handlers. It owns all writes to the MongoDB `logs` collection and is
+// consumed by both the HTTP and gRPC front-ends in cmd/logger-service.
+package logserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cgascoig/coffee-demo-reporting/pkg/logs"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+const (
+	dbName         = "logs"
+	logsCollection = "logs"
+	writeTimeout   = 5 * time.Second
+)
+
+// LogEntry mirrors a document in the `logs` collection.
+type LogEntry struct {
+	Name      string    `bson:"name" json:"name"`
+	Data      string    `bson:"data" json:"data"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Server writes log entries to MongoDB and exposes HTTP and gRPC handlers
+// over that storage.
+type Server struct {
+	log   *logrus.Logger
+	mongo *mongo.Client
+}
+
+// New returns a Server backed by the given MongoDB client.
+func New(log *logrus.Logger, mongoClient *mongo.Client) *Server {
+	return &Server{log: log, mongo: mongoClient}
+}
+
+// WriteLog inserts a log entry, stamping created_at/updated_at.
+func (s *Server) WriteLog(ctx context.Context, name, data string) error {
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+
+	now := time.Now()
+	entry := LogEntry{
+		Name:      name,
+		Data:      data,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	collection := s.mongo.Database(dbName).Collection(logsCollection)
+	_, err := collection.InsertOne(ctx, entry)
+	if err != nil {
+		s.log.Error("Error writing log entry to mongodb: ", err)
+		return err
+	}
+	return nil
+}
+
+// HTTPHandler implements the logger-service's `POST /log` endpoint.
+func (s *Server) HTTPHandler(w http.ResponseWriter, r *http.Request) {
+	var entry struct {
+		Name string `json:"name"`
+		Data string `json:"data"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		s.log.Error("Error decoding log request: ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := s.WriteLog(r.Context(), entry.Name, entry.Data); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GRPCHandler implements logs.LogServiceServer over the same storage.
+type GRPCHandler struct {
+	server *Server
+}
+
+// NewGRPCHandler wraps a Server for registration with a grpc.Server.
+func NewGRPCHandler(s *Server) *GRPCHandler {
+	return &GRPCHandler{server: s}
+}
+
+// WriteLog implements logs.LogServiceServer.
+func (h *GRPCHandler) WriteLog(ctx context.Context, req *logs.LogRequest) (*logs.LogResponse, error) {
+	if err := h.server.WriteLog(ctx, req.GetName(), req.GetData()); err != nil {
+		return &logs.LogResponse{Error: err.Error()}, nil
+	}
+	return &logs.LogResponse{Error: ""}, nil
+}
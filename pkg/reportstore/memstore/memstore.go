@@ -0,0 +1,152 @@
+// Package memstore is an in-memory reportstore.Repository fake, for unit
+// tests that exercise the reporting-service's HTTP layer without a live
+// database.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Store is an in-memory reportstore.Repository backed by plain slices.
+// The zero value is empty; use Seed to populate it.
+type Store struct {
+	orders   []reportstore.Order
+	accounts []reportstore.Account
+}
+
+// New returns an empty Store.
+func New() *Store {
+	return &Store{}
+}
+
+// Seed replaces the Store's contents, for use in test setup.
+func (s *Store) Seed(orders []reportstore.Order, accounts []reportstore.Account) {
+	s.orders = orders
+	s.accounts = accounts
+}
+
+func matches(o reportstore.Order, filter reportstore.Filter) bool {
+	if filter.HasFrom && o.CreatedAt.Before(filter.From) {
+		return false
+	}
+	if filter.HasTo && o.CreatedAt.After(filter.To) {
+		return false
+	}
+	if filter.EmployeeID != "" && o.EmployeeID != filter.EmployeeID {
+		return false
+	}
+	if filter.CoffeeType != "" && o.CoffeeType != filter.CoffeeType {
+		return false
+	}
+	return true
+}
+
+func (s *Store) filtered(filter reportstore.Filter) []reportstore.Order {
+	var out []reportstore.Order
+	for _, o := range s.orders {
+		if matches(o, filter) {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// RecentOrders implements reportstore.Repository.
+func (s *Store) RecentOrders(_ context.Context, filter reportstore.Filter) ([]reportstore.Order, error) {
+	orders := s.filtered(filter)
+
+	start := filter.Offset
+	if start > len(orders) {
+		start = len(orders)
+	}
+	end := start + filter.Limit
+	if filter.Limit <= 0 || end > len(orders) {
+		end = len(orders)
+	}
+
+	return append([]reportstore.Order(nil), orders[start:end]...), nil
+}
+
+// Accounts implements reportstore.Repository.
+func (s *Store) Accounts(_ context.Context, employeeID string) ([]reportstore.Account, error) {
+	if employeeID == "" {
+		return append([]reportstore.Account(nil), s.accounts...), nil
+	}
+
+	var out []reportstore.Account
+	for _, a := range s.accounts {
+		if a.EmployeeID == employeeID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// Authenticate implements reportstore.Repository.
+func (s *Store) Authenticate(_ context.Context, employeeID, password string) (reportstore.Account, error) {
+	for _, a := range s.accounts {
+		if a.EmployeeID != employeeID {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password)) != nil {
+			return reportstore.Account{}, reportstore.ErrInvalidCredentials
+		}
+		a.PasswordHash = ""
+		return a, nil
+	}
+	return reportstore.Account{}, reportstore.ErrInvalidCredentials
+}
+
+// Totals implements reportstore.Repository.
+func (s *Store) Totals(_ context.Context, filter reportstore.Filter) (reportstore.Totals, error) {
+	var totals reportstore.Totals
+	for _, o := range s.filtered(filter) {
+		totals.TotalSales += o.CoffeeQty
+		totals.TotalRevenue += o.Amount
+	}
+	return totals, nil
+}
+
+// Timeseries implements reportstore.Repository.
+func (s *Store) Timeseries(_ context.Context, filter reportstore.Filter, groupBy reportstore.GroupBy) ([]reportstore.TimeseriesPoint, error) {
+	keyFor := func(o reportstore.Order) string {
+		switch groupBy {
+		case reportstore.GroupByEmployee:
+			return o.EmployeeID
+		case reportstore.GroupByCoffeeType:
+			return o.CoffeeType
+		case reportstore.GroupByWeek:
+			year, week := o.CreatedAt.ISOWeek()
+			return fmt.Sprintf("%04d-%02d", year, week)
+		default: // reportstore.GroupByDay
+			return o.CreatedAt.Format("2006-01-02")
+		}
+	}
+
+	byKey := make(map[string]*reportstore.TimeseriesPoint)
+	var keys []string
+	for _, o := range s.filtered(filter) {
+		key := keyFor(o)
+		p, ok := byKey[key]
+		if !ok {
+			p = &reportstore.TimeseriesPoint{Key: key}
+			byKey[key] = p
+			keys = append(keys, key)
+		}
+		p.TotalSales += o.CoffeeQty
+		p.TotalRevenue += o.Amount
+	}
+
+	sort.Strings(keys)
+	points := make([]reportstore.TimeseriesPoint, 0, len(keys))
+	for _, key := range keys {
+		points = append(points, *byKey[key])
+	}
+	return points, nil
+}
@@ -0,0 +1,83 @@
+package memstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+)
+
+func seeded(t *testing.T) *Store {
+	t.Helper()
+	s := New()
+	s.Seed([]reportstore.Order{
+		{ID: "1", CoffeeType: "latte", CoffeeQty: 2, EmployeeID: "alice", Amount: 6.5, CreatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{ID: "2", CoffeeType: "drip", CoffeeQty: 1, EmployeeID: "bob", Amount: 2.5, CreatedAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{ID: "3", CoffeeType: "latte", CoffeeQty: 1, EmployeeID: "alice", Amount: 3.25, CreatedAt: time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)},
+	}, []reportstore.Account{
+		{ID: "a1", EmployeeID: "alice", Balance: 10, Name: "Alice"},
+	})
+	return s
+}
+
+func TestStoreTotals(t *testing.T) {
+	s := seeded(t)
+
+	totals, err := s.Totals(context.Background(), reportstore.Filter{})
+	if err != nil {
+		t.Fatalf("Totals: %v", err)
+	}
+	if totals.TotalSales != 4 {
+		t.Errorf("TotalSales = %d, want 4", totals.TotalSales)
+	}
+	if totals.TotalRevenue != 12.25 {
+		t.Errorf("TotalRevenue = %v, want 12.25", totals.TotalRevenue)
+	}
+}
+
+func TestStoreRecentOrdersFilterAndPagination(t *testing.T) {
+	s := seeded(t)
+
+	orders, err := s.RecentOrders(context.Background(), reportstore.Filter{EmployeeID: "alice", Limit: 10})
+	if err != nil {
+		t.Fatalf("RecentOrders: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("len(orders) = %d, want 2", len(orders))
+	}
+	if orders[0].ID != "3" {
+		t.Errorf("orders[0].ID = %q, want newest-first order %q", orders[0].ID, "3")
+	}
+
+	orders, err = s.RecentOrders(context.Background(), reportstore.Filter{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("RecentOrders: %v", err)
+	}
+	if len(orders) != 1 || orders[0].ID != "2" {
+		t.Fatalf("RecentOrders with offset = %+v, want single order 2", orders)
+	}
+}
+
+func TestStoreTimeseriesGroupByCoffeeType(t *testing.T) {
+	s := seeded(t)
+
+	points, err := s.Timeseries(context.Background(), reportstore.Filter{}, reportstore.GroupByCoffeeType)
+	if err != nil {
+		t.Fatalf("Timeseries: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("len(points) = %d, want 2", len(points))
+	}
+
+	byKey := make(map[string]reportstore.TimeseriesPoint)
+	for _, p := range points {
+		byKey[p.Key] = p
+	}
+	if byKey["latte"].TotalSales != 3 {
+		t.Errorf("latte TotalSales = %d, want 3", byKey["latte"].TotalSales)
+	}
+	if byKey["drip"].TotalSales != 1 {
+		t.Errorf("drip TotalSales = %d, want 1", byKey["drip"].TotalSales)
+	}
+}
@@ -0,0 +1,265 @@
+// Package mongostore is the MongoDB-backed reportstore.Repository, and the
+// reporting-service's original storage behavior.
+package mongostore
+
+import (
+	"context"
+
+	"github.com/cgascoig/coffee-demo-reporting/pkg/reportstore"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/findopt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	dbName                 = "coffee-demo"
+	ordersCollectionName   = "orders"
+	accountsCollectionName = "employeeAccounts"
+)
+
+type order struct {
+	ID         string  `bson:"_id,omitempty"`
+	CoffeeType string  `bson:"coffeetype"`
+	CoffeeQty  int     `bson:"coffeeqty"`
+	EmployeeID string  `bson:"employeeId"`
+	Amount     float32 `bson:"amount"`
+}
+
+type account struct {
+	ID           string  `bson:"_id,omitempty"`
+	EmployeeID   string  `bson:"employeeId"`
+	Balance      float32 `bson:"balance"`
+	Name         string  `bson:"name"`
+	Role         string  `bson:"role,omitempty"`
+	PasswordHash string  `bson:"passwordHash,omitempty"`
+}
+
+func (a account) toReportstore() reportstore.Account {
+	return reportstore.Account{
+		ID:           a.ID,
+		EmployeeID:   a.EmployeeID,
+		Balance:      a.Balance,
+		Name:         a.Name,
+		Role:         a.Role,
+		PasswordHash: a.PasswordHash,
+	}
+}
+
+// Store implements reportstore.Repository against a MongoDB client.
+type Store struct {
+	client *mongo.Client
+}
+
+// New returns a Store backed by the given MongoDB client.
+func New(client *mongo.Client) *Store {
+	return &Store{client: client}
+}
+
+func (s *Store) ordersCollection() *mongo.Collection {
+	return s.client.Database(dbName).Collection(ordersCollectionName)
+}
+
+func (s *Store) accountsCollection() *mongo.Collection {
+	return s.client.Database(dbName).Collection(accountsCollectionName)
+}
+
+// RecentOrders implements reportstore.Repository.
+func (s *Store) RecentOrders(ctx context.Context, filter reportstore.Filter) ([]reportstore.Order, error) {
+	cursor, err := s.ordersCollection().Find(ctx, ordersFilter(filter),
+		findopt.Sort(bson.NewDocument(bson.EC.Int32("_id", -1))),
+		findopt.Limit(int64(filter.Limit)),
+		findopt.Skip(int64(filter.Offset)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []reportstore.Order
+	for cursor.Next(ctx) {
+		var o order
+		if err := cursor.Decode(&o); err == nil {
+			orders = append(orders, reportstore.Order{
+				ID:         o.ID,
+				CoffeeType: o.CoffeeType,
+				CoffeeQty:  o.CoffeeQty,
+				EmployeeID: o.EmployeeID,
+				Amount:     o.Amount,
+			})
+		}
+	}
+	return orders, nil
+}
+
+// Accounts implements reportstore.Repository.
+func (s *Store) Accounts(ctx context.Context, employeeID string) ([]reportstore.Account, error) {
+	var filter *bson.Document
+	if employeeID != "" {
+		filter = bson.NewDocument(bson.EC.String("employeeId", employeeID))
+	}
+
+	cursor, err := s.accountsCollection().Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var accounts []reportstore.Account
+	for cursor.Next(ctx) {
+		var a account
+		if err := cursor.Decode(&a); err == nil {
+			accounts = append(accounts, a.toReportstore())
+		}
+	}
+	return accounts, nil
+}
+
+// Authenticate implements reportstore.Repository.
+func (s *Store) Authenticate(ctx context.Context, employeeID, password string) (reportstore.Account, error) {
+	var a account
+	filter := bson.NewDocument(bson.EC.String("employeeId", employeeID))
+	if err := s.accountsCollection().FindOne(ctx, filter).Decode(&a); err != nil {
+		return reportstore.Account{}, reportstore.ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password)); err != nil {
+		return reportstore.Account{}, reportstore.ErrInvalidCredentials
+	}
+
+	return a.toReportstore(), nil
+}
+
+// Totals implements reportstore.Repository.
+func (s *Store) Totals(ctx context.Context, filter reportstore.Filter) (reportstore.Totals, error) {
+	pipeline := bson.NewArray()
+	if match := matchStage(filter); match != nil {
+		pipeline.Append(bson.VC.Document(match))
+	}
+	pipeline.Append(bson.VC.DocumentFromElements(
+		bson.EC.SubDocumentFromElements(
+			"$group",
+			bson.EC.Int64("_id", 0),
+			bson.EC.SubDocumentFromElements("totalSales", bson.EC.String("$sum", "$coffeeqty")),
+			bson.EC.SubDocumentFromElements("totalRevenue", bson.EC.String("$sum", "$amount")),
+		),
+	))
+
+	cursor, err := s.ordersCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return reportstore.Totals{}, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		return reportstore.Totals{}, nil
+	}
+
+	doc := bson.NewDocument()
+	if err := cursor.Decode(doc); err != nil {
+		return reportstore.Totals{}, err
+	}
+
+	return reportstore.Totals{
+		TotalSales:   int(doc.Lookup("totalSales").Int64()),
+		TotalRevenue: float32(doc.Lookup("totalRevenue").Double()),
+	}, nil
+}
+
+// Timeseries implements reportstore.Repository.
+func (s *Store) Timeseries(ctx context.Context, filter reportstore.Filter, groupBy reportstore.GroupBy) ([]reportstore.TimeseriesPoint, error) {
+	pipeline := bson.NewArray()
+	if match := matchStage(filter); match != nil {
+		pipeline.Append(bson.VC.Document(match))
+	}
+
+	var idElem *bson.Element
+	switch groupBy {
+	case reportstore.GroupByEmployee:
+		idElem = bson.EC.String("_id", "$employeeId")
+	case reportstore.GroupByCoffeeType:
+		idElem = bson.EC.String("_id", "$coffeetype")
+	case reportstore.GroupByWeek:
+		idElem = bson.EC.SubDocumentFromElements("_id",
+			bson.EC.SubDocumentFromElements("$dateToString",
+				bson.EC.String("format", "%Y-%V"),
+				bson.EC.String("date", "$createdAt"),
+			),
+		)
+	default: // reportstore.GroupByDay
+		idElem = bson.EC.SubDocumentFromElements("_id",
+			bson.EC.SubDocumentFromElements("$dateToString",
+				bson.EC.String("format", "%Y-%m-%d"),
+				bson.EC.String("date", "$createdAt"),
+			),
+		)
+	}
+
+	pipeline.Append(bson.VC.DocumentFromElements(
+		bson.EC.SubDocumentFromElements(
+			"$group",
+			idElem,
+			bson.EC.SubDocumentFromElements("totalSales", bson.EC.String("$sum", "$coffeeqty")),
+			bson.EC.SubDocumentFromElements("totalRevenue", bson.EC.String("$sum", "$amount")),
+		),
+	))
+	pipeline.Append(bson.VC.DocumentFromElements(
+		bson.EC.SubDocumentFromElements("$sort", bson.EC.Int32("_id", 1)),
+	))
+
+	cursor, err := s.ordersCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var points []reportstore.TimeseriesPoint
+	for cursor.Next(ctx) {
+		doc := bson.NewDocument()
+		if err := cursor.Decode(doc); err != nil {
+			continue
+		}
+		points = append(points, reportstore.TimeseriesPoint{
+			Key:          doc.Lookup("_id").StringValue(),
+			TotalSales:   int(doc.Lookup("totalSales").Int64()),
+			TotalRevenue: float32(doc.Lookup("totalRevenue").Double()),
+		})
+	}
+	return points, nil
+}
+
+func matchStage(filter reportstore.Filter) *bson.Document {
+	doc := ordersFilter(filter)
+	if doc == nil || doc.Len() == 0 {
+		return nil
+	}
+	return bson.NewDocument(bson.EC.SubDocument("$match", doc))
+}
+
+func ordersFilter(filter reportstore.Filter) *bson.Document {
+	doc := bson.NewDocument()
+
+	if filter.HasFrom || filter.HasTo {
+		rangeDoc := bson.NewDocument()
+		if filter.HasFrom {
+			rangeDoc.Append(bson.EC.DateTime("$gte", filter.From.Unix()*1000))
+		}
+		if filter.HasTo {
+			rangeDoc.Append(bson.EC.DateTime("$lte", filter.To.Unix()*1000))
+		}
+		doc.Append(bson.EC.SubDocument("createdAt", rangeDoc))
+	}
+
+	if filter.EmployeeID != "" {
+		doc.Append(bson.EC.String("employeeId", filter.EmployeeID))
+	}
+
+	if filter.CoffeeType != "" {
+		doc.Append(bson.EC.String("coffeetype", filter.CoffeeType))
+	}
+
+	if doc.Len() == 0 {
+		return nil
+	}
+	return doc
+}
@@ -0,0 +1,96 @@
+// Package reportstore defines the storage-agnostic interface the
+// reporting-service uses to read coffee-demo data. The HTTP layer talks
+// only to a Repository; cmd/reporting-service selects which concrete
+// implementation (mongostore, postgresstore, memstore) backs it via the
+// -store flag.
+package reportstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCredentials is returned by Authenticate when employeeID and
+// password don't match a known account.
+var ErrInvalidCredentials = errors.New("reportstore: invalid credentials")
+
+// Order is a single coffee order.
+type Order struct {
+	ID         string    `json:"_id,omitempty"`
+	CoffeeType string    `json:"coffeetype"`
+	CoffeeQty  int       `json:"coffeeqty"`
+	EmployeeID string    `json:"employeeId"`
+	Amount     float32   `json:"amount"`
+	CreatedAt  time.Time `json:"createdAt,omitempty"`
+}
+
+// Account is an employee's coffee account balance.
+type Account struct {
+	ID         string  `json:"_id,omitempty"`
+	EmployeeID string  `json:"employeeId"`
+	Balance    float32 `json:"balance"`
+	Name       string  `json:"name"`
+	// Role is "admin" or "employee" (see pkg/auth), defaulting to
+	// "employee" when unset. It's never serialized to API responses.
+	Role string `json:"-"`
+	// PasswordHash is the bcrypt hash checked by Authenticate. It's never
+	// serialized to API responses.
+	PasswordHash string `json:"-"`
+}
+
+// Totals summarizes sales across every order matching a Filter.
+type Totals struct {
+	TotalSales   int
+	TotalRevenue float32
+}
+
+// TimeseriesPoint is one bucket of a GroupBy aggregation.
+type TimeseriesPoint struct {
+	Key          string  `json:"key"`
+	TotalSales   int     `json:"totalsales"`
+	TotalRevenue float32 `json:"totalrevenue"`
+}
+
+// GroupBy selects how Timeseries buckets orders.
+type GroupBy string
+
+const (
+	GroupByDay        GroupBy = "day"
+	GroupByWeek       GroupBy = "week"
+	GroupByEmployee   GroupBy = "employee"
+	GroupByCoffeeType GroupBy = "coffeetype"
+)
+
+// Filter narrows RecentOrders/Totals/Timeseries to a date range and/or
+// entity, and paginates RecentOrders.
+type Filter struct {
+	From, To       time.Time
+	HasFrom, HasTo bool
+	EmployeeID     string
+	CoffeeType     string
+	Limit          int
+	Offset         int
+}
+
+// Repository is the storage-agnostic interface the reporting-service's
+// HTTP handlers use to read coffee-demo data. Implementations: mongostore
+// (MongoDB, the original backend), postgresstore (PostgreSQL via pgx),
+// and memstore (an in-memory fake for tests).
+type Repository interface {
+	// RecentOrders returns orders matching filter, newest first,
+	// honoring filter.Limit/Offset.
+	RecentOrders(ctx context.Context, filter Filter) ([]Order, error)
+	// Accounts returns employee accounts, or only employeeID's account if
+	// employeeID is non-empty.
+	Accounts(ctx context.Context, employeeID string) ([]Account, error)
+	// Totals returns the aggregate sales/revenue across orders matching
+	// filter (Limit/Offset are ignored).
+	Totals(ctx context.Context, filter Filter) (Totals, error)
+	// Timeseries buckets orders matching filter by groupBy.
+	Timeseries(ctx context.Context, filter Filter, groupBy GroupBy) ([]TimeseriesPoint, error)
+	// Authenticate verifies employeeID and password against the account's
+	// stored bcrypt hash, returning ErrInvalidCredentials if they don't
+	// match.
+	Authenticate(ctx context.Context, employeeID, password string) (Account, error)
+}